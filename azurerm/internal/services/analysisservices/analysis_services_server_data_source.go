@@ -0,0 +1,141 @@
+package analysisservices
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/tags"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/timeouts"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func dataSourceArmAnalysisServicesServer() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArmAnalysisServicesServerRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"resource_group_name": azure.SchemaResourceGroupNameForDataSource(),
+
+			"location": azure.SchemaLocationForDataSource(),
+
+			"sku": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"admin_users": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"enable_power_bi_service": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"ipv4_firewall_rule": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"range_start": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"range_end": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"querypool_connection_mode": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"server_full_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"tags": tags.SchemaDataSource(),
+		},
+	}
+}
+
+func dataSourceArmAnalysisServicesServerRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*clients.Client).AnalysisServices.ServerClient
+	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	name := d.Get("name").(string)
+	resourceGroup := d.Get("resource_group_name").(string)
+
+	server, err := client.GetDetails(ctx, resourceGroup, name)
+	if err != nil {
+		if utils.ResponseWasNotFound(server.Response) {
+			return fmt.Errorf("Analysis Services Server %q (Resource Group %q) was not found", name, resourceGroup)
+		}
+		return fmt.Errorf("retrieving Analysis Services Server %q (Resource Group %q): %+v", name, resourceGroup, err)
+	}
+
+	if server.ID == nil || *server.ID == "" {
+		return fmt.Errorf("cannot read ID for Analysis Services Server %q (Resource Group %q)", name, resourceGroup)
+	}
+	d.SetId(*server.ID)
+
+	d.Set("name", name)
+	d.Set("resource_group_name", resourceGroup)
+
+	if location := server.Location; location != nil {
+		d.Set("location", azure.NormalizeLocation(*location))
+	}
+
+	if server.Sku != nil {
+		d.Set("sku", server.Sku.Name)
+	}
+
+	if serverProps := server.ServerProperties; serverProps != nil {
+		if serverProps.AsAdministrators == nil {
+			d.Set("admin_users", []string{})
+		} else {
+			d.Set("admin_users", serverProps.AsAdministrators.Members)
+		}
+
+		enablePowerBi, fwRules := flattenAnalysisServicesServerFirewallSettings(serverProps)
+		d.Set("enable_power_bi_service", enablePowerBi)
+		if err := d.Set("ipv4_firewall_rule", fwRules); err != nil {
+			return fmt.Errorf("setting `ipv4_firewall_rule`: %s", err)
+		}
+
+		d.Set("querypool_connection_mode", string(serverProps.QuerypoolConnectionMode))
+		d.Set("server_full_name", serverProps.ServerFullName)
+		d.Set("state", string(serverProps.State))
+	}
+
+	return tags.FlattenAndSet(d, server.Tags)
+}
@@ -1,11 +1,16 @@
 package analysisservices
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"log"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/analysisservices/mgmt/2017-08-01/analysisservices"
+	"github.com/Azure/azure-sdk-for-go/services/monitor/mgmt/2017-05-01-preview/insights"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
@@ -14,6 +19,8 @@ import (
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/features"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/analysisservices/parse"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/analysisservices/validate"
+	keyVaultParse "github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/keyvault/parse"
+	keyVaultValidate "github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/keyvault/validate"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/tags"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/tf/p"
 	azSchema "github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/tf/schema"
@@ -21,6 +28,11 @@ import (
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
 )
 
+var analysisServicesServerDiagnosticLogCategories = []string{
+	"Engine",
+	"Service",
+}
+
 func resourceArmAnalysisServicesServer() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceArmAnalysisServicesServerCreate,
@@ -40,6 +52,8 @@ func resourceArmAnalysisServicesServer() *schema.Resource {
 			return err
 		}),
 
+		CustomizeDiff: validateAnalysisServicesServerFirewallRules,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:         schema.TypeString,
@@ -91,11 +105,12 @@ func resourceArmAnalysisServicesServer() *schema.Resource {
 						"range_start": {
 							Type:         schema.TypeString,
 							Required:     true,
-							ValidateFunc: validation.IsIPv4Address,
+							ValidateFunc: validateIPv4AddressOrCIDR,
 						},
 						"range_end": {
 							Type:         schema.TypeString,
-							Required:     true,
+							Optional:     true,
+							Computed:     true,
 							ValidateFunc: validation.IsIPv4Address,
 						},
 					},
@@ -121,11 +136,177 @@ func resourceArmAnalysisServicesServer() *schema.Resource {
 				Computed: true,
 			},
 
+			"state": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(analysisservices.Running),
+					string(analysisservices.Paused),
+				}, false),
+			},
+
+			"identity": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								string(analysisservices.ResourceIdentityTypeSystemAssigned),
+								string(analysisservices.ResourceIdentityTypeUserAssigned),
+								string(analysisservices.ResourceIdentityTypeSystemAssignedUserAssigned),
+							}, false),
+						},
+
+						"identity_ids": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: azure.ValidateResourceID,
+							},
+						},
+
+						"principal_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"tenant_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"customer_managed_key": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key_vault_key_id": {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateFunc:     keyVaultValidate.NestedItemId,
+							DiffSuppressFunc: diffSuppressKeyVaultKeyIdVersion,
+						},
+
+						"user_assigned_identity_id": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: azure.ValidateResourceID,
+						},
+					},
+				},
+			},
+
+			"diagnostic_setting": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Computed: true,
+						},
+
+						"log_analytics_workspace_id": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: azure.ValidateResourceID,
+						},
+
+						"storage_account_id": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: azure.ValidateResourceID,
+						},
+
+						"eventhub_authorization_rule_id": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: azure.ValidateResourceID,
+						},
+
+						"log": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"category": {
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(analysisServicesServerDiagnosticLogCategories, false),
+									},
+									"enabled": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  true,
+									},
+									"retention_policy": diagnosticSettingRetentionPolicySchema(),
+								},
+							},
+						},
+
+						"metric": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"category": {
+										Type:     schema.TypeString,
+										Optional: true,
+										Default:  "AllMetrics",
+									},
+									"enabled": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  true,
+									},
+									"retention_policy": diagnosticSettingRetentionPolicySchema(),
+								},
+							},
+						},
+					},
+				},
+			},
+
 			"tags": tags.Schema(),
 		},
 	}
 }
 
+func diagnosticSettingRetentionPolicySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"enabled": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"days": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+			},
+		},
+	}
+}
+
 func resourceArmAnalysisServicesServerCreate(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*clients.Client).AnalysisServices.ServerClient
 	ctx, cancel := timeouts.ForCreate(meta.(*clients.Client).StopContext, d)
@@ -149,13 +330,19 @@ func resourceArmAnalysisServicesServerCreate(d *schema.ResourceData, meta interf
 		}
 	}
 
+	serverProperties, err := expandAnalysisServicesServerProperties(d, meta)
+	if err != nil {
+		return err
+	}
+
 	analysisServicesServer := analysisservices.Server{
 		Name:     &name,
 		Location: azure.NormalizeLocationP(d.Get("location")),
 		Sku: &analysisservices.ResourceSku{
 			Name: p.StringI(d.Get("sku")),
 		},
-		ServerProperties: expandAnalysisServicesServerProperties(d),
+		Identity:         expandAnalysisServicesServerIdentity(d.Get("identity").([]interface{})),
+		ServerProperties: serverProperties,
 		Tags:             tags.ExpandI(d.Get("tags")),
 	}
 
@@ -179,6 +366,16 @@ func resourceArmAnalysisServicesServerCreate(d *schema.ResourceData, meta interf
 
 	d.SetId(*resp.ID)
 
+	if v, ok := d.GetOk("state"); ok && v.(string) == string(analysisservices.Paused) {
+		if err := resourceArmAnalysisServicesServerSetPowerState(ctx, meta, resourceGroup, name, analysisservices.Paused); err != nil {
+			return err
+		}
+	}
+
+	if err := resourceArmAnalysisServicesServerUpdateDiagnosticSettings(d, meta, *resp.ID); err != nil {
+		return err
+	}
+
 	return resourceArmAnalysisServicesServerRead(d, meta)
 }
 
@@ -212,6 +409,10 @@ func resourceArmAnalysisServicesServerRead(d *schema.ResourceData, meta interfac
 		d.Set("sku", server.Sku.Name)
 	}
 
+	if err := d.Set("identity", flattenAnalysisServicesServerIdentity(server.Identity)); err != nil {
+		return fmt.Errorf("Error setting `identity`: %s", err)
+	}
+
 	if serverProps := server.ServerProperties; serverProps != nil {
 		if serverProps.AsAdministrators == nil {
 			d.Set("admin_users", []string{})
@@ -227,12 +428,25 @@ func resourceArmAnalysisServicesServerRead(d *schema.ResourceData, meta interfac
 
 		d.Set("querypool_connection_mode", string(serverProps.QuerypoolConnectionMode))
 		d.Set("server_full_name", serverProps.ServerFullName)
+		d.Set("state", string(serverProps.State))
+
+		if err := d.Set("customer_managed_key", flattenAnalysisServicesServerEncryption(serverProps.Encryption)); err != nil {
+			return fmt.Errorf("Error setting `customer_managed_key`: %s", err)
+		}
 
 		if containerUri, ok := d.GetOk("backup_blob_container_uri"); ok {
 			d.Set("backup_blob_container_uri", containerUri)
 		}
 	}
 
+	diagnosticSetting, err := flattenAnalysisServicesServerDiagnosticSettings(d, meta)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("diagnostic_setting", diagnosticSetting); err != nil {
+		return fmt.Errorf("Error setting `diagnostic_setting`: %s", err)
+	}
+
 	return tags.FlattenAndSet(d, server.Tags)
 }
 
@@ -248,12 +462,73 @@ func resourceArmAnalysisServicesServerUpdate(d *schema.ResourceData, meta interf
 		return err
 	}
 
+	hasPropertyChanges := d.HasChange("sku") || d.HasChange("admin_users") || d.HasChange("enable_power_bi_service") ||
+		d.HasChange("ipv4_firewall_rule") || d.HasChange("querypool_connection_mode") || d.HasChange("backup_blob_container_uri") ||
+		d.HasChange("tags") || d.HasChange("identity") || d.HasChange("customer_managed_key")
+
+	propertiesApplied := false
+
+	if d.HasChange("state") {
+		_, newStateRaw := d.GetChange("state")
+		desiredState := analysisservices.State(newStateRaw.(string))
+
+		if desiredState == analysisservices.Paused && hasPropertyChanges {
+			// the server is still running at this point - submit the pending sku/property
+			// changes before pausing it, rather than silently dropping them
+			if err := resourceArmAnalysisServicesServerUpdateProperties(ctx, client, d, meta, id); err != nil {
+				return err
+			}
+			propertiesApplied = true
+		}
+
+		if err := resourceArmAnalysisServicesServerSetPowerState(ctx, meta, id.ResourceGroup, id.Name, desiredState); err != nil {
+			return err
+		}
+
+		if desiredState == analysisservices.Paused {
+			if err := resourceArmAnalysisServicesServerUpdateDiagnosticSettings(d, meta, d.Id()); err != nil {
+				return err
+			}
+			return resourceArmAnalysisServicesServerRead(d, meta)
+		}
+
+		// desiredState == Running: the server has just been resumed, so any pending
+		// sku/property changes can now be submitted below
+	}
+
+	if hasPropertyChanges && !propertiesApplied {
+		// if `state` isn't changing in this apply, the server's last-read state is its current
+		// state - submitting a property update while it's paused is rejected by the API, so
+		// surface a clear error rather than silently dropping the change or sending a bad PATCH
+		if currentState := analysisservices.State(d.Get("state").(string)); currentState == analysisservices.Paused {
+			return fmt.Errorf("cannot update `sku`, `admin_users`, `enable_power_bi_service`, `ipv4_firewall_rule`, `querypool_connection_mode`, `backup_blob_container_uri`, `tags`, `identity` or `customer_managed_key` while the Analysis Services Server is paused - set `state` to %q first", analysisservices.Running)
+		}
+
+		if err := resourceArmAnalysisServicesServerUpdateProperties(ctx, client, d, meta, id); err != nil {
+			return err
+		}
+	}
+
+	if err := resourceArmAnalysisServicesServerUpdateDiagnosticSettings(d, meta, d.Id()); err != nil {
+		return err
+	}
+
+	return resourceArmAnalysisServicesServerRead(d, meta)
+}
+
+func resourceArmAnalysisServicesServerUpdateProperties(ctx context.Context, client analysisservices.ServersClient, d *schema.ResourceData, meta interface{}, id *parse.AnalysisServicesServerId) error {
+	serverMutableProperties, err := expandAnalysisServicesServerMutableProperties(d, meta)
+	if err != nil {
+		return err
+	}
+
 	analysisServicesServer := analysisservices.ServerUpdateParameters{
-		ServerMutableProperties: expandAnalysisServicesServerMutableProperties(d),
+		ServerMutableProperties: serverMutableProperties,
 		Sku: &analysisservices.ResourceSku{
 			Name: p.StringI(d.Get("sku")),
 		},
-		Tags: tags.ExpandI(d.Get("tags")),
+		Identity: expandAnalysisServicesServerIdentity(d.Get("identity").([]interface{})),
+		Tags:     tags.ExpandI(d.Get("tags")),
 	}
 
 	future, err := client.Update(ctx, id.ResourceGroup, id.Name, analysisServicesServer)
@@ -265,7 +540,34 @@ func resourceArmAnalysisServicesServerUpdate(d *schema.ResourceData, meta interf
 		return fmt.Errorf("Error waiting for completion of Analysis Services Server %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
 	}
 
-	return resourceArmAnalysisServicesServerRead(d, meta)
+	return nil
+}
+
+func resourceArmAnalysisServicesServerSetPowerState(ctx context.Context, meta interface{}, resourceGroup, name string, desiredState analysisservices.State) error {
+	client := meta.(*clients.Client).AnalysisServices.ServerClient
+
+	switch desiredState {
+	case analysisservices.Paused:
+		future, err := client.Suspend(ctx, resourceGroup, name)
+		if err != nil {
+			return fmt.Errorf("suspending Analysis Services Server %q (Resource Group %q): %+v", name, resourceGroup, err)
+		}
+		if err := future.WaitForCompletionRef(ctx, client.Client); err != nil {
+			return fmt.Errorf("waiting for suspension of Analysis Services Server %q (Resource Group %q): %+v", name, resourceGroup, err)
+		}
+	case analysisservices.Running:
+		future, err := client.Resume(ctx, resourceGroup, name)
+		if err != nil {
+			return fmt.Errorf("resuming Analysis Services Server %q (Resource Group %q): %+v", name, resourceGroup, err)
+		}
+		if err := future.WaitForCompletionRef(ctx, client.Client); err != nil {
+			return fmt.Errorf("waiting for resume of Analysis Services Server %q (Resource Group %q): %+v", name, resourceGroup, err)
+		}
+	default:
+		return fmt.Errorf("unsupported `state` %q - must be %q or %q", desiredState, analysisservices.Running, analysisservices.Paused)
+	}
+
+	return nil
 }
 
 func resourceArmAnalysisServicesServerDelete(d *schema.ResourceData, meta interface{}) error {
@@ -278,6 +580,10 @@ func resourceArmAnalysisServicesServerDelete(d *schema.ResourceData, meta interf
 		return err
 	}
 
+	if err := resourceArmAnalysisServicesServerDeleteDiagnosticSettings(d, meta, d.Id(), d.Get("diagnostic_setting").([]interface{})); err != nil {
+		return err
+	}
+
 	future, err := client.Delete(ctx, id.ResourceGroup, id.Name)
 	if err != nil {
 		return fmt.Errorf("Error deleting Analysis Services Server %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
@@ -290,7 +596,7 @@ func resourceArmAnalysisServicesServerDelete(d *schema.ResourceData, meta interf
 	return nil
 }
 
-func expandAnalysisServicesServerProperties(d *schema.ResourceData) *analysisservices.ServerProperties {
+func expandAnalysisServicesServerProperties(d *schema.ResourceData, meta interface{}) (*analysisservices.ServerProperties, error) {
 	adminUsers := expandAnalysisServicesServerAdminUsers(d)
 
 	serverProperties := analysisservices.ServerProperties{
@@ -306,10 +612,16 @@ func expandAnalysisServicesServerProperties(d *schema.ResourceData) *analysisser
 		serverProperties.BackupBlobContainerURI = utils.String(containerUri.(string))
 	}
 
-	return &serverProperties
+	encryption, err := expandAnalysisServicesServerEncryption(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	serverProperties.Encryption = encryption
+
+	return &serverProperties, nil
 }
 
-func expandAnalysisServicesServerMutableProperties(d *schema.ResourceData) *analysisservices.ServerMutableProperties {
+func expandAnalysisServicesServerMutableProperties(d *schema.ResourceData, meta interface{}) (*analysisservices.ServerMutableProperties, error) {
 	adminUsers := expandAnalysisServicesServerAdminUsers(d)
 
 	serverProperties := analysisservices.ServerMutableProperties{
@@ -322,7 +634,136 @@ func expandAnalysisServicesServerMutableProperties(d *schema.ResourceData) *anal
 		serverProperties.BackupBlobContainerURI = utils.String(containerUri.(string))
 	}
 
-	return &serverProperties
+	encryption, err := expandAnalysisServicesServerEncryption(d, meta)
+	if err != nil {
+		return nil, err
+	}
+	serverProperties.Encryption = encryption
+
+	return &serverProperties, nil
+}
+
+func expandAnalysisServicesServerIdentity(input []interface{}) *analysisservices.Identity {
+	if len(input) == 0 {
+		return nil
+	}
+
+	raw := input[0].(map[string]interface{})
+
+	identity := analysisservices.Identity{
+		Type: analysisservices.ResourceIdentityType(raw["type"].(string)),
+	}
+
+	identityIds := make(map[string]*analysisservices.IdentityUserAssignedIdentitiesValue)
+	for _, id := range raw["identity_ids"].(*schema.Set).List() {
+		identityIds[id.(string)] = &analysisservices.IdentityUserAssignedIdentitiesValue{}
+	}
+	if len(identityIds) > 0 {
+		identity.UserAssignedIdentities = identityIds
+	}
+
+	return &identity
+}
+
+func flattenAnalysisServicesServerIdentity(input *analysisservices.Identity) []interface{} {
+	if input == nil {
+		return make([]interface{}, 0)
+	}
+
+	identityIds := make([]interface{}, 0)
+	for id := range input.UserAssignedIdentities {
+		identityIds = append(identityIds, id)
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"type":         string(input.Type),
+			"identity_ids": identityIds,
+			"principal_id": p.StrOrEmpty(input.PrincipalID),
+			"tenant_id":    p.StrOrEmpty(input.TenantID),
+		},
+	}
+}
+
+// diffSuppressKeyVaultKeyIdVersion ignores the version segment of a Key Vault Key ID only when the
+// config value is versionless, since a versionless `key_vault_key_id` is resolved to its current,
+// versioned URI before being stored in state - comparing the full URI would otherwise produce a
+// permanent diff for that common case. If the config pins an explicit version (e.g. to rotate the
+// key), the full URI is compared so that change is still applied.
+func diffSuppressKeyVaultKeyIdVersion(_, old, new string, _ *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+
+	newKeyId, err := keyVaultParse.ParseNestedItemID(new)
+	if err != nil {
+		return false
+	}
+
+	if newKeyId.Version != "" {
+		return old == new
+	}
+
+	oldKeyId, err := keyVaultParse.ParseNestedItemID(old)
+	if err != nil {
+		return false
+	}
+
+	return oldKeyId.KeyVaultBaseUrl == newKeyId.KeyVaultBaseUrl && oldKeyId.Name == newKeyId.Name
+}
+
+func expandAnalysisServicesServerEncryption(d *schema.ResourceData, meta interface{}) (*analysisservices.EncryptionConfiguration, error) {
+	cmkRaw := d.Get("customer_managed_key").([]interface{})
+	if len(cmkRaw) == 0 {
+		return nil, nil
+	}
+
+	cmk := cmkRaw[0].(map[string]interface{})
+	keyVaultKeyId := cmk["key_vault_key_id"].(string)
+
+	keyId, err := keyVaultParse.ParseNestedItemID(keyVaultKeyId)
+	if err != nil {
+		return nil, fmt.Errorf("parsing `key_vault_key_id`: %+v", err)
+	}
+
+	keyUri := keyVaultKeyId
+	if keyId.Version == "" {
+		client := meta.(*clients.Client).KeyVault.ManagementClient
+		ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
+		defer cancel()
+
+		key, err := client.GetKey(ctx, keyId.KeyVaultBaseUrl, keyId.Name, "")
+		if err != nil {
+			return nil, fmt.Errorf("retrieving current version of Key %q in Key Vault %q: %+v", keyId.Name, keyId.KeyVaultBaseUrl, err)
+		}
+		if key.Key == nil || key.Key.Kid == nil {
+			return nil, fmt.Errorf("retrieving current version of Key %q in Key Vault %q: `kid` was nil", keyId.Name, keyId.KeyVaultBaseUrl)
+		}
+		keyUri = *key.Key.Kid
+	}
+
+	encryption := analysisservices.EncryptionConfiguration{
+		KeyVaultKeyURI: utils.String(keyUri),
+	}
+
+	if userAssignedIdentityId := cmk["user_assigned_identity_id"].(string); userAssignedIdentityId != "" {
+		encryption.IdentityID = utils.String(userAssignedIdentityId)
+	}
+
+	return &encryption, nil
+}
+
+func flattenAnalysisServicesServerEncryption(input *analysisservices.EncryptionConfiguration) []interface{} {
+	if input == nil {
+		return make([]interface{}, 0)
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"key_vault_key_id":          p.StrOrEmpty(input.KeyVaultKeyURI),
+			"user_assigned_identity_id": p.StrOrEmpty(input.IdentityID),
+		},
+	}
 }
 
 func expandAnalysisServicesServerAdminUsers(d *schema.ResourceData) *analysisservices.ServerAdministrators {
@@ -344,10 +785,11 @@ func expandAnalysisServicesServerFirewallSettings(d *schema.ResourceData) *analy
 
 	for i, v := range firewallRules {
 		fwRule := v.(map[string]interface{})
+		rangeStart, rangeEnd, _ := expandFirewallRuleIPRange(fwRule["range_start"].(string), fwRule["range_end"].(string))
 		fwRules[i] = analysisservices.IPv4FirewallRule{
 			FirewallRuleName: p.StringI(fwRule["name"]),
-			RangeStart:       p.StringI(fwRule["range_start"]),
-			RangeEnd:         p.StringI(fwRule["range_end"]),
+			RangeStart:       utils.String(rangeStart.String()),
+			RangeEnd:         utils.String(rangeEnd.String()),
 		}
 	}
 
@@ -357,6 +799,120 @@ func expandAnalysisServicesServerFirewallSettings(d *schema.ResourceData) *analy
 	}
 }
 
+// expandFirewallRuleIPRange resolves a firewall rule's configured range into concrete
+// start/end IPv4 addresses, expanding CIDR notation supplied via `range_start` when present.
+func expandFirewallRuleIPRange(rawStart, rawEnd string) (start, end net.IP, err error) {
+	if strings.Contains(rawStart, "/") {
+		if rawEnd != "" {
+			return nil, nil, fmt.Errorf("`range_end` must not be set when `range_start` (%q) is CIDR notation", rawStart)
+		}
+
+		ip, ipNet, cidrErr := net.ParseCIDR(rawStart)
+		if cidrErr != nil {
+			return nil, nil, fmt.Errorf("parsing %q as CIDR: %+v", rawStart, cidrErr)
+		}
+		if ip.To4() == nil {
+			return nil, nil, fmt.Errorf("%q is not an IPv4 CIDR block", rawStart)
+		}
+
+		start = ip.Mask(ipNet.Mask)
+
+		broadcast := make(net.IP, len(start))
+		for i := range start {
+			broadcast[i] = start[i] | ^ipNet.Mask[i]
+		}
+		end = broadcast
+
+		return start, end, nil
+	}
+
+	start = net.ParseIP(rawStart)
+	if start == nil || start.To4() == nil {
+		return nil, nil, fmt.Errorf("%q is not a valid IPv4 address", rawStart)
+	}
+
+	end = net.ParseIP(rawEnd)
+	if end == nil || end.To4() == nil {
+		return nil, nil, fmt.Errorf("%q is not a valid IPv4 address", rawEnd)
+	}
+
+	return start, end, nil
+}
+
+func validateIPv4AddressOrCIDR(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %q to be string", k))
+		return warnings, errors
+	}
+
+	if strings.Contains(v, "/") {
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			errors = append(errors, fmt.Errorf("%q is not a valid CIDR block: %+v", k, err))
+		}
+		return warnings, errors
+	}
+
+	return validation.IsIPv4Address(i, k)
+}
+
+func validateAnalysisServicesServerFirewallRules(diff *schema.ResourceDiff, meta interface{}) error {
+	rawRules := diff.Get("ipv4_firewall_rule").(*schema.Set).List()
+
+	return validateFirewallRuleSet(rawRules)
+}
+
+// validateFirewallRuleSet contains the actual `ipv4_firewall_rule` validation logic, kept free of
+// *schema.ResourceDiff so it can be exercised directly from unit tests.
+func validateFirewallRuleSet(rawRules []interface{}) error {
+	type namedRange struct {
+		name  string
+		start net.IP
+		end   net.IP
+	}
+
+	ranges := make([]namedRange, 0, len(rawRules))
+	names := make(map[string]bool)
+
+	for _, v := range rawRules {
+		rule := v.(map[string]interface{})
+		name := rule["name"].(string)
+		rawStart := rule["range_start"].(string)
+		rawEnd := rule["range_end"].(string)
+
+		if !strings.Contains(rawStart, "/") && rawEnd == "" {
+			return fmt.Errorf("`range_end` is required for firewall rule %q when `range_start` is not CIDR notation", name)
+		}
+
+		if names[name] {
+			return fmt.Errorf("firewall rule names must be unique - %q is used more than once", name)
+		}
+		names[name] = true
+
+		start, end, err := expandFirewallRuleIPRange(rawStart, rawEnd)
+		if err != nil {
+			return fmt.Errorf("firewall rule %q: %+v", name, err)
+		}
+
+		if bytes.Compare(start.To4(), end.To4()) > 0 {
+			return fmt.Errorf("firewall rule %q: `range_start` (%s) must not be greater than `range_end` (%s)", name, start, end)
+		}
+
+		ranges = append(ranges, namedRange{name: name, start: start, end: end})
+	}
+
+	for i := 0; i < len(ranges); i++ {
+		for j := i + 1; j < len(ranges); j++ {
+			a, b := ranges[i], ranges[j]
+			if bytes.Compare(a.start.To4(), b.end.To4()) <= 0 && bytes.Compare(b.start.To4(), a.end.To4()) <= 0 {
+				return fmt.Errorf("firewall rules %q and %q have overlapping ranges", a.name, b.name)
+			}
+		}
+	}
+
+	return nil
+}
+
 func flattenAnalysisServicesServerFirewallSettings(serverProperties *analysisservices.ServerProperties) (enablePowerBi *bool, fwRules []interface{}) {
 	if serverProperties == nil || serverProperties.IPV4FirewallSettings == nil {
 		return utils.Bool(false), make([]interface{}, 0)
@@ -384,3 +940,235 @@ func flattenAnalysisServicesServerFirewallSettings(serverProperties *analysisser
 
 	return enablePowerBi, fwRules
 }
+
+func resourceArmAnalysisServicesServerUpdateDiagnosticSettings(d *schema.ResourceData, meta interface{}, resourceId string) error {
+	client := meta.(*clients.Client).Monitor.DiagnosticSettingsClient
+	ctx, cancel := timeouts.ForUpdate(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	oldRaw, newRaw := d.GetChange("diagnostic_setting")
+	diagnosticSettingsRaw := newRaw.([]interface{})
+	if len(diagnosticSettingsRaw) == 0 {
+		// the block was removed from config - delete using the last-known name from state,
+		// since by this point `d.Get("diagnostic_setting")` is already empty too
+		return resourceArmAnalysisServicesServerDeleteDiagnosticSettings(d, meta, resourceId, oldRaw.([]interface{}))
+	}
+
+	settings := diagnosticSettingsRaw[0].(map[string]interface{})
+
+	name := settings["name"].(string)
+	if name == "" {
+		name = fmt.Sprintf("%s-diagnosticsetting", d.Get("name").(string))
+	}
+
+	parameters := insights.DiagnosticSettingsResource{
+		DiagnosticSettings: &insights.DiagnosticSettings{
+			Logs:    expandAnalysisServicesServerDiagnosticLogs(settings["log"].([]interface{})),
+			Metrics: expandAnalysisServicesServerDiagnosticMetrics(settings["metric"].([]interface{})),
+		},
+	}
+
+	if v := settings["log_analytics_workspace_id"].(string); v != "" {
+		parameters.WorkspaceID = utils.String(v)
+	}
+
+	if v := settings["storage_account_id"].(string); v != "" {
+		parameters.StorageAccountID = utils.String(v)
+	}
+
+	if v := settings["eventhub_authorization_rule_id"].(string); v != "" {
+		parameters.EventHubAuthorizationRuleID = utils.String(v)
+	}
+
+	if _, err := client.CreateOrUpdate(ctx, resourceId, parameters, name); err != nil {
+		return fmt.Errorf("creating/updating Diagnostic Setting %q for Analysis Services Server %q: %+v", name, d.Get("name").(string), err)
+	}
+
+	return nil
+}
+
+func resourceArmAnalysisServicesServerDeleteDiagnosticSettings(d *schema.ResourceData, meta interface{}, resourceId string, diagnosticSettingsRaw []interface{}) error {
+	if len(diagnosticSettingsRaw) == 0 {
+		return nil
+	}
+
+	settings := diagnosticSettingsRaw[0].(map[string]interface{})
+	name := settings["name"].(string)
+	if name == "" {
+		name = fmt.Sprintf("%s-diagnosticsetting", d.Get("name").(string))
+	}
+
+	client := meta.(*clients.Client).Monitor.DiagnosticSettingsClient
+	ctx, cancel := timeouts.ForDelete(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	if _, err := client.Delete(ctx, resourceId, name); err != nil {
+		return fmt.Errorf("removing Diagnostic Setting %q for Analysis Services Server %q: %+v", name, d.Get("name").(string), err)
+	}
+
+	return nil
+}
+
+func expandAnalysisServicesServerDiagnosticLogs(input []interface{}) *[]insights.LogSettings {
+	logs := make([]insights.LogSettings, 0)
+
+	for _, v := range input {
+		raw := v.(map[string]interface{})
+
+		log := insights.LogSettings{
+			Category: utils.String(raw["category"].(string)),
+			Enabled:  utils.Bool(raw["enabled"].(bool)),
+		}
+
+		if policy, ok := expandAnalysisServicesServerDiagnosticRetentionPolicy(raw["retention_policy"].([]interface{})); ok {
+			log.RetentionPolicy = policy
+		}
+
+		logs = append(logs, log)
+	}
+
+	return &logs
+}
+
+func expandAnalysisServicesServerDiagnosticMetrics(input []interface{}) *[]insights.MetricSettings {
+	metrics := make([]insights.MetricSettings, 0)
+
+	for _, v := range input {
+		raw := v.(map[string]interface{})
+
+		metric := insights.MetricSettings{
+			Category: utils.String(raw["category"].(string)),
+			Enabled:  utils.Bool(raw["enabled"].(bool)),
+		}
+
+		if policy, ok := expandAnalysisServicesServerDiagnosticRetentionPolicy(raw["retention_policy"].([]interface{})); ok {
+			metric.RetentionPolicy = policy
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return &metrics
+}
+
+func expandAnalysisServicesServerDiagnosticRetentionPolicy(input []interface{}) (*insights.RetentionPolicy, bool) {
+	if len(input) == 0 {
+		return nil, false
+	}
+
+	raw := input[0].(map[string]interface{})
+
+	return &insights.RetentionPolicy{
+		Enabled: utils.Bool(raw["enabled"].(bool)),
+		Days:    utils.Int32(int32(raw["days"].(int))),
+	}, true
+}
+
+func flattenAnalysisServicesServerDiagnosticSettings(d *schema.ResourceData, meta interface{}) ([]interface{}, error) {
+	diagnosticSettingsRaw := d.Get("diagnostic_setting").([]interface{})
+	if len(diagnosticSettingsRaw) == 0 {
+		return make([]interface{}, 0), nil
+	}
+
+	settings := diagnosticSettingsRaw[0].(map[string]interface{})
+	name := settings["name"].(string)
+	if name == "" {
+		name = fmt.Sprintf("%s-diagnosticsetting", d.Get("name").(string))
+	}
+
+	client := meta.(*clients.Client).Monitor.DiagnosticSettingsClient
+	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	resp, err := client.Get(ctx, d.Id(), name)
+	if err != nil {
+		if utils.ResponseWasNotFound(resp.Response) {
+			return make([]interface{}, 0), nil
+		}
+		return nil, fmt.Errorf("retrieving Diagnostic Setting %q for Analysis Services Server %q: %+v", name, d.Get("name").(string), err)
+	}
+
+	output := make(map[string]interface{})
+	output["name"] = name
+	output["log_analytics_workspace_id"] = p.StrOrEmpty(resp.WorkspaceID)
+	output["storage_account_id"] = p.StrOrEmpty(resp.StorageAccountID)
+	output["eventhub_authorization_rule_id"] = p.StrOrEmpty(resp.EventHubAuthorizationRuleID)
+
+	if resp.DiagnosticSettings != nil {
+		output["log"] = flattenAnalysisServicesServerDiagnosticLogs(resp.Logs)
+		output["metric"] = flattenAnalysisServicesServerDiagnosticMetrics(resp.Metrics)
+	}
+
+	return []interface{}{output}, nil
+}
+
+func flattenAnalysisServicesServerDiagnosticLogs(input *[]insights.LogSettings) []interface{} {
+	logs := make([]interface{}, 0)
+	if input == nil {
+		return logs
+	}
+
+	for _, v := range *input {
+		enabled := false
+		if v.Enabled != nil {
+			enabled = *v.Enabled
+		}
+
+		output := map[string]interface{}{
+			"category":         p.StrOrEmpty(v.Category),
+			"enabled":          enabled,
+			"retention_policy": flattenAnalysisServicesServerDiagnosticRetentionPolicy(v.RetentionPolicy),
+		}
+
+		logs = append(logs, output)
+	}
+
+	return logs
+}
+
+func flattenAnalysisServicesServerDiagnosticMetrics(input *[]insights.MetricSettings) []interface{} {
+	metrics := make([]interface{}, 0)
+	if input == nil {
+		return metrics
+	}
+
+	for _, v := range *input {
+		enabled := false
+		if v.Enabled != nil {
+			enabled = *v.Enabled
+		}
+
+		output := map[string]interface{}{
+			"category":         p.StrOrEmpty(v.Category),
+			"enabled":          enabled,
+			"retention_policy": flattenAnalysisServicesServerDiagnosticRetentionPolicy(v.RetentionPolicy),
+		}
+
+		metrics = append(metrics, output)
+	}
+
+	return metrics
+}
+
+func flattenAnalysisServicesServerDiagnosticRetentionPolicy(input *insights.RetentionPolicy) []interface{} {
+	if input == nil {
+		return make([]interface{}, 0)
+	}
+
+	enabled := false
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	days := 0
+	if input.Days != nil {
+		days = int(*input.Days)
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"enabled": enabled,
+			"days":    days,
+		},
+	}
+}
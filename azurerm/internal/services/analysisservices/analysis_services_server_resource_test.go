@@ -0,0 +1,186 @@
+package analysisservices
+
+import "testing"
+
+func TestExpandFirewallRuleIPRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		rawStart      string
+		rawEnd        string
+		expectedStart string
+		expectedEnd   string
+		expectError   bool
+	}{
+		{
+			name:          "plain IPv4 range",
+			rawStart:      "10.0.0.1",
+			rawEnd:        "10.0.0.10",
+			expectedStart: "10.0.0.1",
+			expectedEnd:   "10.0.0.10",
+		},
+		{
+			name:          "CIDR block",
+			rawStart:      "10.0.0.0/24",
+			expectedStart: "10.0.0.0",
+			expectedEnd:   "10.0.0.255",
+		},
+		{
+			name:        "CIDR block with range_end set is rejected",
+			rawStart:    "10.0.0.0/24",
+			rawEnd:      "10.0.0.255",
+			expectError: true,
+		},
+		{
+			name:        "invalid CIDR block",
+			rawStart:    "10.0.0.0/abc",
+			expectError: true,
+		},
+		{
+			name:        "invalid range_start",
+			rawStart:    "not-an-ip",
+			rawEnd:      "10.0.0.10",
+			expectError: true,
+		},
+		{
+			name:        "invalid range_end",
+			rawStart:    "10.0.0.1",
+			rawEnd:      "not-an-ip",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, err := expandFirewallRuleIPRange(tc.rawStart, tc.rawEnd)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected an error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if start.String() != tc.expectedStart {
+				t.Fatalf("expected start %q but got %q", tc.expectedStart, start.String())
+			}
+			if end.String() != tc.expectedEnd {
+				t.Fatalf("expected end %q but got %q", tc.expectedEnd, end.String())
+			}
+		})
+	}
+}
+
+func TestValidateIPv4AddressOrCIDR(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expectError bool
+	}{
+		{name: "valid IPv4 address", value: "10.0.0.1"},
+		{name: "valid CIDR block", value: "10.0.0.0/24"},
+		{name: "invalid CIDR block", value: "10.0.0.0/abc", expectError: true},
+		{name: "not an address or CIDR block", value: "not-an-ip", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errs := validateIPv4AddressOrCIDR(tc.value, "range_start")
+			if tc.expectError && len(errs) == 0 {
+				t.Fatalf("expected an error but got none")
+			}
+			if !tc.expectError && len(errs) != 0 {
+				t.Fatalf("unexpected error(s): %+v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateFirewallRuleSet(t *testing.T) {
+	rule := func(name, rangeStart, rangeEnd string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"range_start": rangeStart,
+			"range_end":   rangeEnd,
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		rules       []interface{}
+		expectError bool
+	}{
+		{
+			name: "non-overlapping ranges",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.0", "10.0.0.10"),
+				rule("rule2", "10.0.1.0", "10.0.1.10"),
+			},
+		},
+		{
+			name: "adjacent CIDR blocks do not overlap",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.0/24", ""),
+				rule("rule2", "10.0.1.0/24", ""),
+			},
+		},
+		{
+			name: "overlapping ranges",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.0", "10.0.0.10"),
+				rule("rule2", "10.0.0.5", "10.0.0.15"),
+			},
+			expectError: true,
+		},
+		{
+			name: "overlapping CIDR blocks",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.0/23", ""),
+				rule("rule2", "10.0.1.0/24", ""),
+			},
+			expectError: true,
+		},
+		{
+			name: "duplicate rule names",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.0", "10.0.0.10"),
+				rule("rule1", "10.0.1.0", "10.0.1.10"),
+			},
+			expectError: true,
+		},
+		{
+			name: "range_start greater than range_end",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.10", "10.0.0.1"),
+			},
+			expectError: true,
+		},
+		{
+			name: "range_end missing for non-CIDR range_start",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.1", ""),
+			},
+			expectError: true,
+		},
+		{
+			name: "range_end set alongside CIDR range_start",
+			rules: []interface{}{
+				rule("rule1", "10.0.0.0/24", "10.0.0.255"),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateFirewallRuleSet(tc.rules)
+			if tc.expectError && err == nil {
+				t.Fatalf("expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+		})
+	}
+}